@@ -0,0 +1,230 @@
+// Package cfg lowers a single function's AST into a simple control-flow
+// graph. It exists alongside the line-by-line complexity walker in the root
+// package to catch functions that look simple statement-by-statement but
+// have a tangled overall shape: many basic blocks, a high cyclomatic number,
+// or a large number of variables alive at once.
+package cfg
+
+import "go/ast"
+
+// BasicBlock is a straight-line run of statements with no internal control
+// flow. Execution enters at the top and, unless the block ends in a Return
+// statement, falls through to every block in Succs.
+type BasicBlock struct {
+	Index int
+	Stmts []ast.Stmt
+	Succs []*BasicBlock
+	Preds []*BasicBlock
+}
+
+// Function is a simplified control-flow graph for a single *ast.FuncDecl.
+type Function struct {
+	Decl   *ast.FuncDecl
+	Blocks []*BasicBlock
+	Entry  *BasicBlock
+}
+
+// Cyclomatic returns the cyclomatic complexity of f using the standard
+// E - N + 2P formula, where E is the edge count, N is the block count and P
+// is the number of connected components (almost always 1 for a single
+// function).
+func (f *Function) Cyclomatic() int {
+	if len(f.Blocks) == 0 {
+		return 0
+	}
+
+	edges := 0
+	for _, block := range f.Blocks {
+		edges += len(block.Succs)
+	}
+
+	return edges - len(f.Blocks) + 2*componentCount(f.Blocks)
+}
+
+func componentCount(blocks []*BasicBlock) int {
+	visited := make(map[*BasicBlock]bool)
+	components := 0
+
+	for _, start := range blocks {
+		if visited[start] {
+			continue
+		}
+
+		components++
+		stack := []*BasicBlock{start}
+		for len(stack) > 0 {
+			block := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if visited[block] {
+				continue
+			}
+			visited[block] = true
+
+			stack = append(stack, block.Succs...)
+			stack = append(stack, block.Preds...)
+		}
+	}
+
+	return components
+}
+
+type builder struct {
+	fn *Function
+}
+
+// Build lowers fn into a control-flow graph. fn.Body may be nil (an
+// external/assembly declaration), in which case an empty Function with no
+// blocks is returned.
+func Build(fn *ast.FuncDecl) *Function {
+	f := &Function{Decl: fn}
+	if fn.Body == nil {
+		return f
+	}
+
+	b := &builder{fn: f}
+	entry := b.newBlock()
+	f.Entry = entry
+	b.build(entry, fn.Body.List)
+
+	return f
+}
+
+func (b *builder) newBlock() *BasicBlock {
+	block := &BasicBlock{Index: len(b.fn.Blocks)}
+	b.fn.Blocks = append(b.fn.Blocks, block)
+
+	return block
+}
+
+func link(from, to *BasicBlock) {
+	from.Succs = append(from.Succs, to)
+	to.Preds = append(to.Preds, from)
+}
+
+// build appends stmts to current, splitting into new blocks at every
+// terminator (If, For, Range, Switch, TypeSwitch, Select, Defer, Go) and
+// returns the block execution falls through to afterwards, or nil once a
+// Return is reached; statements after a Return are not added.
+func (b *builder) build(current *BasicBlock, stmts []ast.Stmt) *BasicBlock {
+	for _, stmt := range stmts {
+		current.Stmts = append(current.Stmts, stmt)
+
+		switch n := stmt.(type) {
+		case *ast.IfStmt:
+			current = b.buildIf(current, n)
+
+		case *ast.ForStmt:
+			current = b.buildLoop(current, n.Body.List)
+
+		case *ast.RangeStmt:
+			current = b.buildLoop(current, n.Body.List)
+
+		case *ast.SwitchStmt:
+			current = b.buildCases(current, n.Body.List)
+
+		case *ast.TypeSwitchStmt:
+			current = b.buildCases(current, n.Body.List)
+
+		case *ast.SelectStmt:
+			current = b.buildCases(current, n.Body.List)
+
+		case *ast.DeferStmt, *ast.GoStmt:
+			next := b.newBlock()
+			link(current, next)
+			current = next
+
+		case *ast.ReturnStmt:
+			return nil
+		}
+	}
+
+	return current
+}
+
+func (b *builder) buildIf(current *BasicBlock, n *ast.IfStmt) *BasicBlock {
+	thenBlock := b.newBlock()
+	link(current, thenBlock)
+	thenExit := b.build(thenBlock, n.Body.List)
+
+	var elseExit *BasicBlock
+	hasElse := n.Else != nil
+	if hasElse {
+		elseBlock := b.newBlock()
+		link(current, elseBlock)
+
+		if block, ok := n.Else.(*ast.BlockStmt); ok {
+			elseExit = b.build(elseBlock, block.List)
+		} else {
+			elseExit = b.build(elseBlock, []ast.Stmt{n.Else})
+		}
+	}
+
+	join := b.newBlock()
+	if thenExit != nil {
+		link(thenExit, join)
+	}
+	if !hasElse {
+		link(current, join)
+	} else if elseExit != nil {
+		link(elseExit, join)
+	}
+
+	return join
+}
+
+func (b *builder) buildLoop(current *BasicBlock, body []ast.Stmt) *BasicBlock {
+	header := b.newBlock()
+	link(current, header)
+
+	bodyBlock := b.newBlock()
+	link(header, bodyBlock)
+
+	if bodyExit := b.build(bodyBlock, body); bodyExit != nil {
+		link(bodyExit, header)
+	}
+
+	after := b.newBlock()
+	link(header, after)
+
+	return after
+}
+
+// buildCases models Switch, TypeSwitch and Select uniformly: every clause is
+// a block reachable from current, and control reaches join either from the
+// fallthrough-free end of each clause or, when there is no default clause,
+// directly from current.
+func (b *builder) buildCases(current *BasicBlock, clauses []ast.Stmt) *BasicBlock {
+	join := b.newBlock()
+	sawDefault := false
+
+	for _, clause := range clauses {
+		var body []ast.Stmt
+
+		switch c := clause.(type) {
+		case *ast.CaseClause:
+			body = c.Body
+			sawDefault = sawDefault || c.List == nil
+
+		case *ast.CommClause:
+			body = c.Body
+			sawDefault = sawDefault || c.Comm == nil
+
+		default:
+			continue
+		}
+
+		caseBlock := b.newBlock()
+		link(current, caseBlock)
+
+		if exit := b.build(caseBlock, body); exit != nil {
+			link(exit, join)
+		}
+	}
+
+	if !sawDefault {
+		link(current, join)
+	}
+
+	return join
+}
@@ -0,0 +1,75 @@
+package cfg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFunc(t *testing.T, body string) *ast.FuncDecl {
+	src := fmt.Sprintf("package p\n\nfunc a() {\n%s\n}\n", body)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, 0)
+	assert.NoError(t, err)
+
+	return file.Decls[0].(*ast.FuncDecl)
+}
+
+func TestBuild_StraightLine(t *testing.T) {
+	fn := parseFunc(t, `x := 1
+y := 2
+_ = x + y`)
+
+	f := Build(fn)
+	assert.Len(t, f.Blocks, 1)
+	assert.Equal(t, 1, f.Cyclomatic())
+}
+
+func TestBuild_If(t *testing.T) {
+	fn := parseFunc(t, `if true {
+x := 1
+_ = x
+}`)
+
+	f := Build(fn)
+	assert.Equal(t, 2, f.Cyclomatic())
+}
+
+func TestBuild_IfElse(t *testing.T) {
+	fn := parseFunc(t, `if true {
+x := 1
+_ = x
+} else {
+y := 2
+_ = y
+}`)
+
+	f := Build(fn)
+	assert.Equal(t, 2, f.Cyclomatic())
+}
+
+func TestBuild_For(t *testing.T) {
+	fn := parseFunc(t, `for i := 0; i < 10; i++ {
+_ = i
+}`)
+
+	f := Build(fn)
+	assert.Equal(t, 2, f.Cyclomatic())
+}
+
+func TestAnalyzeLiveness(t *testing.T) {
+	fn := parseFunc(t, `x := 1
+y := 2
+if x > 0 {
+_ = y
+}`)
+
+	f := Build(fn)
+	liveness := AnalyzeLiveness(f)
+	assert.True(t, liveness.Max >= 1)
+}
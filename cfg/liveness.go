@@ -0,0 +1,250 @@
+package cfg
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Liveness holds the live-in and live-out variable sets for every block in
+// a Function, along with Max, the largest number of variables live on entry
+// to or exit from any single block. Points between the statements of a
+// block are not measured.
+type Liveness struct {
+	In  map[*BasicBlock]map[string]bool
+	Out map[*BasicBlock]map[string]bool
+	Max int
+}
+
+// AnalyzeLiveness computes live-in/live-out sets by iterating the standard
+// backward data-flow equations (in = use ∪ (out − def), out = ∪ succ.in)
+// to a fixed point. It uses Rename to tell a genuine live-in requirement
+// (a read with no local definition earlier in the same block) from a read
+// of a value the block produced and consumed itself, which the flat
+// source-level name would otherwise over-count as a use.
+func AnalyzeLiveness(f *Function) *Liveness {
+	renamed := Rename(f)
+
+	l := &Liveness{
+		In:  make(map[*BasicBlock]map[string]bool),
+		Out: make(map[*BasicBlock]map[string]bool),
+	}
+
+	uses := make(map[*BasicBlock]map[string]bool)
+	defs := make(map[*BasicBlock]map[string]bool)
+
+	for _, block := range f.Blocks {
+		u, d := useDef(block.Stmts, renamed)
+		uses[block] = u
+		defs[block] = d
+		l.In[block] = map[string]bool{}
+		l.Out[block] = map[string]bool{}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, block := range f.Blocks {
+			out := map[string]bool{}
+			for _, succ := range block.Succs {
+				for name := range l.In[succ] {
+					out[name] = true
+				}
+			}
+
+			in := map[string]bool{}
+			for name := range uses[block] {
+				in[name] = true
+			}
+			for name := range out {
+				if !defs[block][name] {
+					in[name] = true
+				}
+			}
+
+			if !setEqual(in, l.In[block]) || !setEqual(out, l.Out[block]) {
+				changed = true
+			}
+
+			l.In[block] = in
+			l.Out[block] = out
+		}
+	}
+
+	for _, block := range f.Blocks {
+		if n := len(l.In[block]); n > l.Max {
+			l.Max = n
+		}
+		if n := len(l.Out[block]); n > l.Max {
+			l.Max = n
+		}
+	}
+
+	return l
+}
+
+func setEqual(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name := range a {
+		if !b[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// useDef returns the names used and defined by a straight-line run of
+// statements, ignoring nested block bodies (those belong to their own
+// BasicBlock and are analyzed separately). renamed is Rename's output for
+// the whole function: an identifier with an entry in renamed resolves to a
+// version defined earlier in this same block, so it is a locally-produced
+// value rather than a genuine live-in requirement and is excluded from
+// uses.
+func useDef(stmts []ast.Stmt, renamed map[*ast.Ident]Var) (uses, defs map[string]bool) {
+	uses = map[string]bool{}
+	defs = map[string]bool{}
+
+	for _, stmt := range stmts {
+		switch n := stmt.(type) {
+		case *ast.AssignStmt:
+			for _, rhs := range n.Rhs {
+				collectIdents(rhs, renamed, uses)
+			}
+			assignReadsAndDefs(n.Lhs, n.Tok, renamed, uses, defs)
+
+		case *ast.DeclStmt:
+			gen, ok := n.Decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+
+				for _, value := range vs.Values {
+					collectIdents(value, renamed, uses)
+				}
+				for _, name := range vs.Names {
+					if name.Name != "_" {
+						defs[name.Name] = true
+					}
+				}
+			}
+
+		case *ast.ExprStmt:
+			collectIdents(n.X, renamed, uses)
+
+		case *ast.ReturnStmt:
+			for _, result := range n.Results {
+				collectIdents(result, renamed, uses)
+			}
+
+		case *ast.IfStmt:
+			collectIdents(n.Cond, renamed, uses)
+
+		case *ast.ForStmt:
+			// Only the init statement and condition are read here: the body
+			// belongs to the loop's own BasicBlocks and the post statement is
+			// not analyzed.
+			initUses, initDefs := useDef([]ast.Stmt{n.Init}, renamed)
+			collectIdents(n.Cond, renamed, initUses)
+			for name := range initUses {
+				if !initDefs[name] {
+					uses[name] = true
+				}
+			}
+			for name := range initDefs {
+				defs[name] = true
+			}
+
+		case *ast.SwitchStmt:
+			if n.Tag != nil {
+				collectIdents(n.Tag, renamed, uses)
+			}
+
+		case *ast.RangeStmt:
+			collectIdents(n.X, renamed, uses)
+			assignReadsAndDefs([]ast.Expr{n.Key, n.Value}, n.Tok, renamed, uses, defs)
+
+		case *ast.TypeSwitchStmt:
+			switch assign := n.Assign.(type) {
+			case *ast.AssignStmt:
+				// "switch v := x.(type)": x is read here; the per-case guard
+				// variable v belongs to each case's own BasicBlock.
+				for _, rhs := range assign.Rhs {
+					collectIdents(rhs, renamed, uses)
+				}
+
+			case *ast.ExprStmt:
+				// "switch x.(type)" with no guard variable.
+				collectIdents(assign.X, renamed, uses)
+			}
+
+		case *ast.SelectStmt:
+			// No head expression to read; every comm clause is its own
+			// BasicBlock, handled when that block is visited.
+
+		default:
+			collectIdents(stmt, renamed, uses)
+		}
+	}
+
+	return uses, defs
+}
+
+// assignReadsAndDefs classifies each lhs target of an AssignStmt or a
+// RangeStmt's key/value bindings. A bare identifier assigned with ":=" or
+// "=" is a pure definition. Anything else — a compound-assignment target
+// (x += 1) or a non-ident target (arr[i], obj.Field) — also reads whatever
+// it's built from, since it requires the prior value to produce the new
+// one, and never introduces a new name binding of its own.
+func assignReadsAndDefs(lhs []ast.Expr, tok token.Token, renamed map[*ast.Ident]Var, uses, defs map[string]bool) {
+	for _, l := range lhs {
+		if l == nil {
+			continue
+		}
+
+		ident, isIdent := l.(*ast.Ident)
+		plainAssign := isIdent && (tok == token.DEFINE || tok == token.ASSIGN)
+
+		if !plainAssign {
+			collectIdents(l, renamed, uses)
+		}
+
+		if isIdent && ident.Name != "_" {
+			defs[ident.Name] = true
+		}
+	}
+}
+
+// collectIdents records every identifier reachable from node that is not
+// already mapped in renamed (meaning it resolves to a definition earlier in
+// this same block), treating a selector's base ("foo" in "foo.bar") as a
+// use but never its field name.
+func collectIdents(node ast.Node, renamed map[*ast.Ident]Var, out map[string]bool) {
+	if node == nil {
+		return
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			collectIdents(sel.X, renamed, out)
+
+			return false
+		}
+
+		if ident, ok := n.(*ast.Ident); ok && ident.Name != "_" {
+			if _, shadowed := renamed[ident]; !shadowed {
+				out[ident.Name] = true
+			}
+		}
+
+		return true
+	})
+}
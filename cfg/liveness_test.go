@@ -0,0 +1,68 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeLiveness_CompoundAssignRequiresLiveIn(t *testing.T) {
+	fn := parseFunc(t, `x := 0
+if x > 0 {
+	x += 1
+}
+_ = x`)
+
+	f := Build(fn)
+	liveness := AnalyzeLiveness(f)
+
+	thenBlock := f.Blocks[1]
+	assert.True(t, liveness.In[thenBlock]["x"], "x += 1 reads x before writing it")
+}
+
+func TestAnalyzeLiveness_IndexedAssignReadsBaseAndIndex(t *testing.T) {
+	fn := parseFunc(t, `arr := []int{1, 2, 3}
+i := 0
+if i >= 0 {
+	arr[i] = 5
+}`)
+
+	f := Build(fn)
+	liveness := AnalyzeLiveness(f)
+
+	thenBlock := f.Blocks[1]
+	assert.True(t, liveness.In[thenBlock]["arr"], "arr[i] = 5 reads arr, it doesn't redefine it")
+	assert.True(t, liveness.In[thenBlock]["i"], "arr[i] = 5 reads i")
+}
+
+func TestAnalyzeLiveness_RangeKeyValueDoNotLeakToEntry(t *testing.T) {
+	fn := parseFunc(t, `m := map[string]int{}
+for k, v := range m {
+	_ = k
+	_ = v
+}`)
+
+	f := Build(fn)
+	liveness := AnalyzeLiveness(f)
+
+	assert.False(t, liveness.In[f.Entry]["k"], "k is bound by the range clause, not required from outside")
+	assert.False(t, liveness.In[f.Entry]["v"], "v is bound by the range clause, not required from outside")
+}
+
+func TestUseDef_TypeSwitchOnlyReadsTypeAssertOperand(t *testing.T) {
+	fn := parseFunc(t, `switch v := x.(type) {
+case int:
+	_ = v
+case string:
+	_ = v
+}`)
+
+	f := Build(fn)
+	renamed := Rename(f)
+	uses, _ := useDef(f.Entry.Stmts, renamed)
+
+	assert.True(t, uses["x"], "the type-assert operand is a genuine read")
+	assert.False(t, uses["v"], "the guard variable belongs to each case's own block")
+	assert.False(t, uses["int"], "a case type name is not an identifier read")
+	assert.False(t, uses["string"], "a case type name is not an identifier read")
+}
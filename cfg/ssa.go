@@ -0,0 +1,147 @@
+package cfg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// Var is a single SSA-renamed occurrence of a source-level identifier.
+type Var struct {
+	Name    string
+	Version int
+}
+
+func (v Var) String() string {
+	return fmt.Sprintf("%s.%d", v.Name, v.Version)
+}
+
+// Rename performs a simple phi-less SSA renaming of local variables: each
+// definition of a name within a block is assigned the next unused version
+// for that name, tracked in a per-block symbol table, and every subsequent
+// read of that name within the same block is tagged with the version it
+// resolves to. A read that resolves to no entry in the returned map refers
+// to a value that must come from outside the block (a parameter, an
+// outer-scope variable, or whatever version was live on entry) — that
+// distinction is what AnalyzeLiveness uses to tell a genuine live-in
+// requirement from a name that is defined and consumed entirely within one
+// block. Versions are not unified across block boundaries, which is why
+// this is "SSA-lite" rather than a full SSA construction with phi nodes.
+func Rename(f *Function) map[*ast.Ident]Var {
+	renamed := make(map[*ast.Ident]Var)
+
+	for _, block := range f.Blocks {
+		versions := make(map[string]int)
+
+		for _, stmt := range block.Stmts {
+			switch n := stmt.(type) {
+			case *ast.AssignStmt:
+				renameAssign(n.Rhs, n.Lhs, n.Tok, versions, renamed)
+
+			case *ast.RangeStmt:
+				renameReads(n.X, versions, renamed)
+				renameAssign(nil, []ast.Expr{n.Key, n.Value}, n.Tok, versions, renamed)
+
+			case *ast.DeclStmt:
+				if gen, ok := n.Decl.(*ast.GenDecl); ok {
+					for _, spec := range gen.Specs {
+						if vs, ok := spec.(*ast.ValueSpec); ok {
+							names := make([]ast.Expr, len(vs.Names))
+							for i, name := range vs.Names {
+								names[i] = name
+							}
+							renameAssign(vs.Values, names, token.DEFINE, versions, renamed)
+						}
+					}
+				}
+
+			default:
+				renameReads(headNode(stmt), versions, renamed)
+			}
+		}
+	}
+
+	return renamed
+}
+
+// renameAssign handles the shared def/read logic of an AssignStmt and a
+// RangeStmt's key/value bindings: each rhs is always read, and each lhs is
+// either a fresh definition (a bare identifier under ":=" or "=") or, for a
+// compound assignment or a non-ident target (arr[i], obj.Field), a read of
+// whatever it's built from, since it requires the prior value to produce
+// the new one.
+func renameAssign(rhs, lhs []ast.Expr, tok token.Token, versions map[string]int, renamed map[*ast.Ident]Var) {
+	for _, r := range rhs {
+		renameReads(r, versions, renamed)
+	}
+
+	for _, l := range lhs {
+		if l == nil {
+			continue
+		}
+
+		ident, isIdent := l.(*ast.Ident)
+		plainAssign := isIdent && (tok == token.DEFINE || tok == token.ASSIGN)
+
+		if !plainAssign {
+			renameReads(l, versions, renamed)
+		}
+
+		if isIdent && ident.Name != "_" {
+			versions[ident.Name]++
+
+			if plainAssign {
+				renamed[ident] = Var{Name: ident.Name, Version: versions[ident.Name]}
+			}
+		}
+	}
+}
+
+// headNode returns the part of stmt that Rename should inspect for reads,
+// excluding any nested block body: If/For/Switch/TypeSwitch/Select bodies
+// belong to their own BasicBlock and are renamed independently when that
+// block is visited. AssignStmt, RangeStmt and DeclStmt are handled directly
+// by Rename's main loop, since all three also introduce definitions.
+func headNode(stmt ast.Stmt) ast.Node {
+	switch n := stmt.(type) {
+	case *ast.IfStmt:
+		return n.Cond
+
+	case *ast.ForStmt:
+		return n.Cond
+
+	case *ast.SwitchStmt:
+		return n.Tag
+
+	case *ast.TypeSwitchStmt, *ast.SelectStmt:
+		return nil
+
+	default:
+		return stmt
+	}
+}
+
+// renameReads tags every identifier reachable from node that already has a
+// version in scope for this block (i.e. one defined earlier in the same
+// block), leaving identifiers with no local definition yet unmapped.
+func renameReads(node ast.Node, versions map[string]int, renamed map[*ast.Ident]Var) {
+	if node == nil {
+		return
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			renameReads(sel.X, versions, renamed)
+
+			return false
+		}
+
+		if ident, ok := n.(*ast.Ident); ok {
+			if v, ok := versions[ident.Name]; ok {
+				renamed[ident] = Var{Name: ident.Name, Version: v}
+			}
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,111 @@
+package cfg
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// xIdents returns every *ast.Ident named "x" in fn, in source order.
+func xIdents(fn *ast.FuncDecl) []*ast.Ident {
+	var idents []*ast.Ident
+
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == "x" {
+			idents = append(idents, ident)
+		}
+
+		return true
+	})
+
+	return idents
+}
+
+func TestRename_DefinitionsGetSuccessiveVersions(t *testing.T) {
+	fn := parseFunc(t, `x := 1
+x = x + 1
+_ = x`)
+
+	renamed := Rename(Build(fn))
+	idents := xIdents(fn)
+	assert.Len(t, idents, 4) // "x :=", "x =", "x" (in x+1), "x" (in _ = x)
+
+	assert.Equal(t, Var{Name: "x", Version: 1}, renamed[idents[0]])
+	assert.Equal(t, Var{Name: "x", Version: 2}, renamed[idents[1]])
+}
+
+func TestRename_ReadsResolveToTheReachingDefinition(t *testing.T) {
+	fn := parseFunc(t, `x := 1
+x = x + 1
+_ = x`)
+
+	renamed := Rename(Build(fn))
+	idents := xIdents(fn)
+
+	// The "x" on the right of "x = x + 1" reads the value from "x := 1".
+	assert.Equal(t, Var{Name: "x", Version: 1}, renamed[idents[2]])
+
+	// The "x" in "_ = x" reads the value from "x = x + 1".
+	assert.Equal(t, Var{Name: "x", Version: 2}, renamed[idents[3]])
+}
+
+func TestRename_UnresolvedReadHasNoEntry(t *testing.T) {
+	fn := parseFunc(t, `_ = x`)
+
+	renamed := Rename(Build(fn))
+	idents := xIdents(fn)
+	assert.Len(t, idents, 1)
+
+	_, ok := renamed[idents[0]]
+	assert.False(t, ok, "a read with no prior local definition must be unmapped")
+}
+
+func TestRename_CompoundAssignWithNoPriorDefIsUnresolved(t *testing.T) {
+	fn := parseFunc(t, `x += 1`)
+
+	renamed := Rename(Build(fn))
+	idents := xIdents(fn)
+	assert.Len(t, idents, 1)
+
+	_, ok := renamed[idents[0]]
+	assert.False(t, ok, "x += 1 with no prior definition in this block reads from outside it")
+}
+
+func TestRename_CompoundAssignReadsThenDefines(t *testing.T) {
+	fn := parseFunc(t, `x := 1
+x += 2
+_ = x`)
+
+	renamed := Rename(Build(fn))
+	idents := xIdents(fn)
+	assert.Len(t, idents, 3) // "x :=", "x +=", "x" (in _ = x)
+
+	// "x += 2" reads the value from "x := 1" ...
+	assert.Equal(t, Var{Name: "x", Version: 1}, renamed[idents[1]])
+	// ... and "_ = x" reads the value written by "x += 2".
+	assert.Equal(t, Var{Name: "x", Version: 2}, renamed[idents[2]])
+}
+
+func TestRename_RangeKeyValueAreFreshDefinitions(t *testing.T) {
+	fn := parseFunc(t, `for k, v := range m {
+	_ = k
+	_ = v
+}`)
+
+	f := Build(fn)
+	renamed := Rename(f)
+
+	var kIdent, vIdent *ast.Ident
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if r, ok := n.(*ast.RangeStmt); ok {
+			kIdent = r.Key.(*ast.Ident)
+			vIdent = r.Value.(*ast.Ident)
+		}
+
+		return true
+	})
+
+	assert.Equal(t, Var{Name: "k", Version: 1}, renamed[kIdent])
+	assert.Equal(t, Var{Name: "v", Version: 1}, renamed[vIdent])
+}
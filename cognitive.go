@@ -0,0 +1,255 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cognitiveComplexity scores fn the way a human reader would find it hard to
+// follow, as opposed to the flat per-expression scoring in exprComplexity:
+// control structures cost more the deeper they are nested, while a flat
+// sequence of guard clauses or a long run of the same boolean operator costs
+// comparatively little.
+//
+// The rules, applied while walking fn.Body with a running nesting counter:
+//
+//   - Entering an if/for/range/switch/type-switch/select/func literal adds
+//     1+nesting to the score, then increments nesting for its body.
+//   - Each "else"/"else if" link in an if-chain adds 1, with no nesting
+//     bonus and no further nesting increment.
+//   - A labeled break/continue/goto adds 1.
+//   - A run of the same &&/|| operator inside a boolean expression adds 1;
+//     switching operator type within the same expression adds another 1.
+//   - A direct recursive call to fn adds 1.
+func cognitiveComplexity(fn *ast.FuncDecl) int {
+	if fn.Body == nil {
+		return 0
+	}
+
+	w := &cognitiveWalker{funcName: fn.Name.Name}
+	w.walkStmts(fn.Body.List, 0)
+
+	return w.score
+}
+
+type cognitiveWalker struct {
+	funcName string
+	score    int
+}
+
+func (w *cognitiveWalker) walkStmts(stmts []ast.Stmt, nesting int) {
+	for _, stmt := range stmts {
+		w.walkStmt(stmt, nesting)
+	}
+}
+
+func (w *cognitiveWalker) walkStmt(stmt ast.Stmt, nesting int) {
+	switch n := stmt.(type) {
+	case *ast.IfStmt:
+		w.walkIfChain(n, nesting)
+
+	case *ast.ForStmt:
+		w.score += 1 + nesting
+		w.walkStmt(n.Init, nesting)
+		w.walkExpr(n.Cond, nesting)
+		w.walkStmt(n.Post, nesting)
+		w.walkStmts(n.Body.List, nesting+1)
+
+	case *ast.RangeStmt:
+		w.score += 1 + nesting
+		w.walkExpr(n.X, nesting)
+		w.walkStmts(n.Body.List, nesting+1)
+
+	case *ast.SwitchStmt:
+		w.score += 1 + nesting
+		w.walkExpr(n.Tag, nesting)
+		w.walkStmts(n.Body.List, nesting+1)
+
+	case *ast.TypeSwitchStmt:
+		w.score += 1 + nesting
+		w.walkStmts(n.Body.List, nesting+1)
+
+	case *ast.SelectStmt:
+		w.score += 1 + nesting
+		w.walkStmts(n.Body.List, nesting+1)
+
+	case *ast.CaseClause:
+		for _, e := range n.List {
+			w.walkExpr(e, nesting)
+		}
+		w.walkStmts(n.Body, nesting)
+
+	case *ast.CommClause:
+		w.walkStmts(n.Body, nesting)
+
+	case *ast.BranchStmt:
+		if n.Label != nil {
+			w.score++
+		}
+
+	case *ast.BlockStmt:
+		w.walkStmts(n.List, nesting)
+
+	case *ast.LabeledStmt:
+		w.walkStmt(n.Stmt, nesting)
+
+	case *ast.ExprStmt:
+		w.walkExpr(n.X, nesting)
+
+	case *ast.AssignStmt:
+		for _, e := range n.Rhs {
+			w.walkExpr(e, nesting)
+		}
+
+	case *ast.ReturnStmt:
+		for _, e := range n.Results {
+			w.walkExpr(e, nesting)
+		}
+
+	case *ast.SendStmt:
+		w.walkExpr(n.Value, nesting)
+
+	case *ast.DeferStmt:
+		w.walkExpr(n.Call, nesting)
+
+	case *ast.GoStmt:
+		w.walkExpr(n.Call, nesting)
+
+	case *ast.DeclStmt:
+		gen, ok := n.Decl.(*ast.GenDecl)
+		if !ok {
+			return
+		}
+
+		for _, spec := range gen.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok {
+				for _, e := range vs.Values {
+					w.walkExpr(e, nesting)
+				}
+			}
+		}
+	}
+}
+
+// walkIfChain scores an if/else-if/else chain as a flat sequence: the
+// initial "if" adds 1+nesting, and every subsequent "else"/"else if" link
+// adds a plain 1, without being re-scored as a nested "if" of its own.
+func (w *cognitiveWalker) walkIfChain(n *ast.IfStmt, nesting int) {
+	w.score += 1 + nesting
+	w.walkExpr(n.Cond, nesting)
+	w.walkStmts(n.Body.List, nesting+1)
+
+	for {
+		switch e := n.Else.(type) {
+		case *ast.BlockStmt:
+			w.score++
+			w.walkStmts(e.List, nesting+1)
+
+			return
+
+		case *ast.IfStmt:
+			w.score++
+			w.walkExpr(e.Cond, nesting)
+			w.walkStmts(e.Body.List, nesting+1)
+			n = e
+
+		default:
+			return
+		}
+	}
+}
+
+func (w *cognitiveWalker) walkExpr(expr ast.Expr, nesting int) {
+	switch e := expr.(type) {
+	case nil:
+		return
+
+	case *ast.BinaryExpr:
+		if e.Op == token.LAND || e.Op == token.LOR {
+			w.walkLogicalChain(e, nesting)
+		} else {
+			w.walkExpr(e.X, nesting)
+			w.walkExpr(e.Y, nesting)
+		}
+
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == w.funcName {
+			w.score++
+		}
+
+		w.walkExpr(e.Fun, nesting)
+		for _, arg := range e.Args {
+			w.walkExpr(arg, nesting)
+		}
+
+	case *ast.UnaryExpr:
+		w.walkExpr(e.X, nesting)
+
+	case *ast.StarExpr:
+		w.walkExpr(e.X, nesting)
+
+	case *ast.ParenExpr:
+		w.walkExpr(e.X, nesting)
+
+	case *ast.TypeAssertExpr:
+		w.walkExpr(e.X, nesting)
+
+	case *ast.IndexExpr:
+		w.walkExpr(e.X, nesting)
+		w.walkExpr(e.Index, nesting)
+
+	case *ast.KeyValueExpr:
+		w.walkExpr(e.Value, nesting)
+
+	case *ast.SelectorExpr:
+		w.walkExpr(e.X, nesting)
+
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			w.walkExpr(elt, nesting)
+		}
+
+	case *ast.SliceExpr:
+		w.walkExpr(e.Low, nesting)
+		w.walkExpr(e.High, nesting)
+		w.walkExpr(e.Max, nesting)
+
+	case *ast.FuncLit:
+		w.score += 1 + nesting
+		w.walkStmts(e.Body.List, nesting+1)
+	}
+}
+
+// walkLogicalChain scores a run of &&/|| operators within a single boolean
+// expression: the chain adds 1 for its first operator and another 1 each
+// time the operator type changes, regardless of how many operands it has.
+// Leaf operands (which may themselves contain calls or nested expressions)
+// are walked normally once the chain has been flattened.
+func (w *cognitiveWalker) walkLogicalChain(root *ast.BinaryExpr, nesting int) {
+	var ops []token.Token
+	var leaves []ast.Expr
+
+	var flatten func(expr ast.Expr)
+	flatten = func(expr ast.Expr) {
+		if b, ok := expr.(*ast.BinaryExpr); ok && (b.Op == token.LAND || b.Op == token.LOR) {
+			flatten(b.X)
+			ops = append(ops, b.Op)
+			flatten(b.Y)
+
+			return
+		}
+
+		leaves = append(leaves, expr)
+	}
+	flatten(root)
+
+	for i, op := range ops {
+		if i == 0 || op != ops[i-1] {
+			w.score++
+		}
+	}
+
+	for _, leaf := range leaves {
+		w.walkExpr(leaf, nesting)
+	}
+}
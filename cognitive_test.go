@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/elliotchance/tf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCognitiveComplexity(t *testing.T) {
+	fn := func(body string) int {
+		src := fmt.Sprintf("package p\nfunc a() {\n%s\n}\n", body)
+
+		fset := token.NewFileSet()
+		node, err := parser.ParseFile(fset, "test.go", src, 0)
+		assert.NoError(t, err)
+
+		return cognitiveComplexity(node.Decls[0].(*ast.FuncDecl))
+	}
+
+	CC := tf.NamedFunction(t, "NestedLoops", fn)
+	CC(`for i := 0; i < 10; i++ {}`).Returns(1)
+	CC(`for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {}
+	}`).Returns(3)
+	CC(`for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			for k := 0; k < 10; k++ {}
+		}
+	}`).Returns(6)
+
+	CC = tf.NamedFunction(t, "GuardClause", fn)
+	CC(`if a {
+		return
+	}
+	if b {
+		return
+	}
+	if c {
+		return
+	}`).Returns(3)
+	CC(`if a {
+		return
+	} else if b {
+		return
+	} else {
+		return
+	}`).Returns(3)
+
+	CC = tf.NamedFunction(t, "BooleanChain", fn)
+	CC(`if a && b {}`).Returns(2)
+	CC(`if a && b && c {}`).Returns(2)
+	CC(`if a && b || c {}`).Returns(3)
+	CC(`if a && b || c && d {}`).Returns(4)
+
+	CC = tf.NamedFunction(t, "Lambda", fn)
+	CC(`f := func() {}
+	_ = f`).Returns(1)
+	CC(`f := func() {
+		if a {
+		}
+	}
+	_ = f`).Returns(3)
+
+	CC = tf.NamedFunction(t, "Recursion", fn)
+	CC(`a()`).Returns(1)
+	CC(`if x {
+		a()
+	}`).Returns(2)
+
+	CC = tf.NamedFunction(t, "LabeledBranch", fn)
+	CC(`loop:
+	for {
+		break loop
+	}`).Returns(2)
+}
@@ -0,0 +1,234 @@
+// Package directives parses the "//ghost:" pragma comments that control
+// ghost's analysis, modeled on the Go compiler's "//go:" pragmas. A Table
+// pre-scans a file's comments once and lets callers ask for the effective
+// Settings at file, function and statement scope.
+package directives
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// Settings is the effective set of directives in force at a given point in
+// the source. Zero values mean "inherit from the enclosing scope" except
+// where noted.
+type Settings struct {
+	MaxLineComplexity int
+	Metric            string
+	IgnoreFunction    bool
+
+	// IgnoreLine is set by a "//ghost:ignore" comment. Inside a function
+	// body a Cursor sets it only for the single statement the comment
+	// precedes. A "//ghost:ignore" before the package clause or in a
+	// function's doc comment sets it in FileSettings or FunctionSettings,
+	// and so for every statement in that scope.
+	IgnoreLine bool
+}
+
+// Directive is a single parsed "//ghost:name arg" comment.
+type Directive struct {
+	Name string
+	Arg  string
+	Pos  token.Pos
+	End  token.Pos
+}
+
+// Table holds every directive comment found in a file, ready to be queried
+// at whichever scope a caller needs.
+type Table struct {
+	file       *ast.File
+	directives []Directive
+
+	// Errors holds one message per unknown or malformed directive comment,
+	// so typos surface loudly instead of being silently ignored.
+	Errors []string
+}
+
+// Parse pre-scans file.Comments once and builds a Table. Comment groups
+// that are a *ast.FuncDecl's doc comment are excluded from statement-scope
+// lookups; they are only visible through FunctionSettings.
+func Parse(fset *token.FileSet, file *ast.File) *Table {
+	t := &Table{file: file}
+
+	docGroups := make(map[*ast.CommentGroup]bool)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Doc != nil {
+			docGroups[fn.Doc] = true
+		}
+	}
+
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			name, arg, ok := parseComment(c.Text)
+			if !ok {
+				continue
+			}
+
+			d := Directive{Name: name, Arg: arg, Pos: group.Pos(), End: group.End()}
+
+			if err := validate(d); err != nil {
+				t.Errors = append(t.Errors,
+					fmt.Sprintf("%s: %s", fset.Position(c.Pos()), err))
+
+				continue
+			}
+
+			if docGroups[group] {
+				// Handled exclusively through FunctionSettings.
+				continue
+			}
+
+			t.directives = append(t.directives, d)
+		}
+	}
+
+	return t
+}
+
+// parseComment recognises "// ghost:name" and "// ghost:name arg..." lines,
+// returning ok=false for anything that isn't a ghost directive at all.
+func parseComment(text string) (name, arg string, ok bool) {
+	line := strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	if !strings.HasPrefix(line, "ghost:") {
+		return "", "", false
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "ghost:"))
+	if len(fields) == 0 {
+		return "", "", false
+	}
+
+	return fields[0], strings.Join(fields[1:], " "), true
+}
+
+func validate(d Directive) error {
+	switch d.Name {
+	case "max-line-complexity":
+		if d.Arg == "" {
+			return fmt.Errorf("ghost:max-line-complexity requires an integer argument")
+		}
+		if _, err := strconv.Atoi(d.Arg); err != nil {
+			return fmt.Errorf("ghost:max-line-complexity argument %q is not an integer", d.Arg)
+		}
+
+	case "metric":
+		if d.Arg == "" {
+			return fmt.Errorf("ghost:metric requires a metric name")
+		}
+
+	case "ignore-function", "ignore":
+		if d.Arg != "" {
+			return fmt.Errorf("ghost:%s takes no argument", d.Name)
+		}
+
+	default:
+		return fmt.Errorf("unknown directive %q", "ghost:"+d.Name)
+	}
+
+	return nil
+}
+
+func apply(s *Settings, d Directive) {
+	switch d.Name {
+	case "max-line-complexity":
+		n, _ := strconv.Atoi(d.Arg)
+		s.MaxLineComplexity = n
+
+	case "metric":
+		s.Metric = d.Arg
+
+	case "ignore-function":
+		s.IgnoreFunction = true
+
+	case "ignore":
+		s.IgnoreLine = true
+	}
+}
+
+// FileSettings returns base overridden by every directive comment that
+// appears before the "package" clause.
+func (t *Table) FileSettings(base Settings) Settings {
+	for _, d := range t.directives {
+		if d.Pos >= t.file.Package {
+			break
+		}
+
+		apply(&base, d)
+	}
+
+	return base
+}
+
+// FunctionSettings returns base overridden by fn's doc comment directives.
+func (t *Table) FunctionSettings(fn *ast.FuncDecl, base Settings) Settings {
+	if fn.Doc == nil {
+		return base
+	}
+
+	for _, c := range fn.Doc.List {
+		name, arg, ok := parseComment(c.Text)
+		if !ok {
+			continue
+		}
+
+		d := Directive{Name: name, Arg: arg, Pos: c.Pos(), End: c.End()}
+		if validate(d) != nil {
+			continue // already reported in Parse
+		}
+
+		apply(&base, d)
+	}
+
+	return base
+}
+
+// Cursor walks a single function body applying block-scoped directives to
+// the statement they immediately precede.
+type Cursor struct {
+	directives []Directive
+	index      int
+}
+
+// BodyCursor returns a Cursor restricted to the directives lexically inside
+// fn's body.
+func (t *Table) BodyCursor(fn *ast.FuncDecl) *Cursor {
+	c := &Cursor{}
+	if fn.Body == nil {
+		return c
+	}
+
+	for _, d := range t.directives {
+		if d.Pos >= fn.Body.Pos() && d.Pos < fn.Body.End() {
+			c.directives = append(c.directives, d)
+		}
+	}
+
+	return c
+}
+
+// Settings returns base overridden by every not-yet-consumed directive that
+// starts before the end of stmt (whether it precedes stmt at the same scope, such
+// as a "//ghost:ignore" on the line above, or is nested inside stmt's own
+// body, such as one inside an "if"/"for"/"switch" block), advancing the
+// cursor past them. A directive nested inside stmt is scoped to stmt itself
+// rather than leaking onto whichever sibling statement happens to follow it
+// lexically, since checkFunction only scores top-level statements.
+// IgnoreLine only applies to this one call's result; it is never carried
+// over to the next statement because the caller always passes the original
+// function-scope Settings back in on the next call.
+func (c *Cursor) Settings(stmt ast.Stmt, base Settings) Settings {
+	for c.index < len(c.directives) {
+		d := c.directives[c.index]
+		if d.Pos >= stmt.End() {
+			break
+		}
+
+		apply(&base, d)
+		c.index++
+	}
+
+	return base
+}
@@ -0,0 +1,109 @@
+package directives
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, src string) (*token.FileSet, *ast.File) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	assert.NoError(t, err)
+
+	return fset, file
+}
+
+func TestFileSettings(t *testing.T) {
+	fset, file := parse(t, "//ghost:max-line-complexity 9\npackage p\n\nfunc a() {}\n")
+
+	table := Parse(fset, file)
+	assert.Empty(t, table.Errors)
+
+	settings := table.FileSettings(Settings{MaxLineComplexity: 5})
+	assert.Equal(t, 9, settings.MaxLineComplexity)
+}
+
+func TestFunctionSettings(t *testing.T) {
+	fset, file := parse(t, "package p\n\n// ghost:ignore-function\nfunc a() {}\n")
+
+	table := Parse(fset, file)
+	fn := file.Decls[0].(*ast.FuncDecl)
+
+	settings := table.FunctionSettings(fn, Settings{MaxLineComplexity: 5})
+	assert.True(t, settings.IgnoreFunction)
+}
+
+func TestBodyCursorIgnore(t *testing.T) {
+	fset, file := parse(t, `package p
+
+func a() {
+	x := 1
+	// ghost:ignore
+	y := foo(bar()) + baz(qux())
+	_ = x
+	_ = y
+}
+`)
+
+	table := Parse(fset, file)
+	fn := file.Decls[0].(*ast.FuncDecl)
+	base := Settings{MaxLineComplexity: 1}
+
+	cursor := table.BodyCursor(fn)
+	var ignoredCount int
+	for _, stmt := range fn.Body.List {
+		if cursor.Settings(stmt, base).IgnoreLine {
+			ignoredCount++
+		}
+	}
+
+	assert.Equal(t, 1, ignoredCount)
+}
+
+func TestBodyCursorNestedIgnoreDoesNotLeak(t *testing.T) {
+	fset, file := parse(t, `package p
+
+func a() {
+	if true {
+		// ghost:ignore
+		foo(bar())
+	}
+	baz(qux())
+}
+`)
+
+	table := Parse(fset, file)
+	fn := file.Decls[0].(*ast.FuncDecl)
+	base := Settings{MaxLineComplexity: 0}
+
+	cursor := table.BodyCursor(fn)
+	var results []bool
+	for _, stmt := range fn.Body.List {
+		results = append(results, cursor.Settings(stmt, base).IgnoreLine)
+	}
+
+	// The nested "//ghost:ignore" is consumed while scoring the enclosing
+	// "if" (the only top-level statement it lexically falls inside), never
+	// leaking onto the unrelated sibling statement that follows it.
+	assert.False(t, results[1], "sibling statement after the if must still be checked")
+}
+
+func TestUnknownDirectiveIsReported(t *testing.T) {
+	fset, file := parse(t, "package p\n\n// ghost:bogus\nfunc a() {}\n")
+
+	table := Parse(fset, file)
+	assert.Len(t, table.Errors, 1)
+}
+
+func TestMalformedMaxLineComplexityIsReported(t *testing.T) {
+	fset, file := parse(t, "package p\n\n// ghost:max-line-complexity nope\nfunc a() {}\n")
+
+	table := Parse(fset, file)
+	assert.Len(t, table.Errors, 1)
+}
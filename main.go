@@ -1,114 +1,626 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/printer"
 	"go/token"
 	"os"
-	"strings"
+	"runtime"
 	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/elliotchance/ghost/cfg"
+	"github.com/elliotchance/ghost/directives"
 )
 
-var fset *token.FileSet
-var file *ast.File
-var currentFunction *ast.FuncDecl
-var hasErrors bool
 var (
 	optionIgnoreTests       bool
 	optionMaxLineComplexity int
 	optionNeverFail         bool
+	optionFix               bool
+	optionWriteInPlace      bool
+	optionMetric            string
+	optionFormat            string
+	optionMaxCognitive      int
+	optionMaxCyclomatic     int
+	optionMaxLiveness       int
+	optionMaxBlocks         int
 )
-var commentGroupIndex int
 
 func main() {
 	flag.BoolVar(&optionNeverFail, "never-fail", false, "Always exit with 0.")
 	flag.BoolVar(&optionIgnoreTests, "ignore-tests", false, "Ignore test files.")
 	flag.IntVar(&optionMaxLineComplexity, "max-line-complexity", 5,
 		"The maximum allowed line complexity.")
+	flag.BoolVar(&optionFix, "fix", false,
+		"Automatically hoist complex sub-expressions into temporary variables "+
+			"to bring offending lines under max-line-complexity.")
+	flag.BoolVar(&optionWriteInPlace, "w", false,
+		"With -fix, write the result back to the file instead of stdout.")
+	flag.StringVar(&optionMetric, "metric", "line",
+		"The complexity metric to use: line, cyclomatic, liveness, blocks, or cognitive.")
+	flag.StringVar(&optionFormat, "format", "text",
+		"Output format: text, json, or sarif.")
+	flag.IntVar(&optionMaxCognitive, "max-cognitive", 15,
+		"The maximum allowed cognitive complexity (only used by -metric cognitive).")
+	flag.IntVar(&optionMaxCyclomatic, "max-cyclomatic", 10,
+		"The maximum allowed cyclomatic complexity (only used by -metric cyclomatic).")
+	flag.IntVar(&optionMaxLiveness, "max-liveness", 10,
+		"The maximum allowed number of variables simultaneously live (only used by -metric liveness).")
+	flag.IntVar(&optionMaxBlocks, "max-blocks", 10,
+		"The maximum allowed number of basic blocks (only used by -metric blocks).")
 	flag.Parse()
 
-	for _, currentFile := range os.Args[1:] {
-		if !strings.HasSuffix(currentFile, ".go") {
+	files := resolveFiles(flag.Args())
+	rep := &reporter{}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range jobs {
+				analyzeFile(filename, rep)
+			}
+		}()
+	}
+
+	for _, filename := range files {
+		jobs <- filename
+	}
+	close(jobs)
+	wg.Wait()
+
+	rep.flush()
+
+	if rep.hasErrors && !optionNeverFail {
+		os.Exit(1)
+	}
+}
+
+// resolveFiles expands patterns into a deduplicated, optionally
+// test-filtered list of Go source files. A pattern ending in ".go" is
+// treated as a literal file (the historical command-line interface);
+// everything else (a directory or a package pattern such as "./...") is
+// resolved with go/packages.
+func resolveFiles(patterns []string) []string {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	var files []string
+	var pkgPatterns []string
+
+	for _, p := range patterns {
+		if strings.HasSuffix(p, ".go") {
+			files = append(files, p)
+		} else {
+			pkgPatterns = append(pkgPatterns, p)
+		}
+	}
+
+	if len(pkgPatterns) > 0 {
+		pkgs, err := packages.Load(&packages.Config{
+			Mode:  packages.NeedName | packages.NeedFiles,
+			Tests: true,
+		}, pkgPatterns...)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, pkg := range pkgs {
+			files = append(files, pkg.GoFiles...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	deduped := files[:0]
+	for _, f := range files {
+		if seen[f] {
 			continue
 		}
+		seen[f] = true
 
-		if optionIgnoreTests && strings.HasSuffix(currentFile, "_test.go") {
+		if optionIgnoreTests && strings.HasSuffix(f, "_test.go") {
 			continue
 		}
 
-		var err error
-		fset = token.NewFileSet()
-		file, err = parser.ParseFile(fset, currentFile, nil, parser.ParseComments)
-		if err != nil {
-			panic(err)
+		deduped = append(deduped, f)
+	}
+
+	return deduped
+}
+
+// finding is a single reportable complexity violation, shaped so it can be
+// rendered as plain text, JSON or SARIF.
+type finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Function string `json:"function,omitempty"`
+	Message  string `json:"message"`
+}
+
+// reporter serializes output from the concurrent workers in main. Every
+// worker analyzes its own file with its own *analyzer, but all of them
+// funnel violations through this single mutex-guarded reporter so stdout is
+// never written to from more than one goroutine at a time.
+type reporter struct {
+	mu        sync.Mutex
+	hasErrors bool
+	findings  []finding
+}
+
+func (r *reporter) report(f finding) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hasErrors = true
+	r.findings = append(r.findings, f)
+
+	if optionFormat == "text" {
+		fmt.Println(f.Message)
+	}
+}
+
+// flush prints the accumulated findings in json or sarif format. It must
+// only be called after every worker has finished; text format is streamed
+// as findings arrive in report instead.
+func (r *reporter) flush() {
+	findings := r.findings
+	if findings == nil {
+		// A nil slice encodes as the JSON/SARIF literal null; callers doing
+		// for-range over "results" expect an array even when there's
+		// nothing to report.
+		findings = []finding{}
+	}
+
+	switch optionFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(findings)
+
+	case "sarif":
+		printSARIF(findings)
+	}
+}
+
+// analyzer holds everything that used to be package-level state (fset,
+// file, currentFunction, the directive cursor). Each worker goroutine in
+// main constructs its own analyzer per file, so no state is shared across
+// goroutines.
+type analyzer struct {
+	fset            *token.FileSet
+	file            *ast.File
+	currentFunction *ast.FuncDecl
+	directiveTable  *directives.Table
+	fileSettings    directives.Settings
+	tempNameCounter int
+}
+
+func analyzeFile(filename string, rep *reporter) {
+	a := &analyzer{fset: token.NewFileSet()}
+
+	var err error
+	a.file, err = parser.ParseFile(a.fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		panic(err)
+	}
+
+	a.directiveTable = directives.Parse(a.fset, a.file)
+	for _, msg := range a.directiveTable.Errors {
+		rep.report(finding{File: filename, Message: msg})
+	}
+	a.fileSettings = a.directiveTable.FileSettings(directives.Settings{
+		MaxLineComplexity: optionMaxLineComplexity,
+		Metric:            optionMetric,
+	})
+
+	if optionFix {
+		a.fixFile(filename, rep)
+		return
+	}
+
+	for _, decl := range a.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
 		}
 
-		commentGroupIndex = 0
+		metric := a.fileSettings.Metric
+		if settings := a.directiveTable.FunctionSettings(fn, a.fileSettings); settings.Metric != "" {
+			metric = settings.Metric
+		}
 
-		for _, decl := range file.Decls {
-			fn, ok := decl.(*ast.FuncDecl)
-			if !ok {
-				continue
-			}
+		if metric == "line" {
+			a.checkFunction(rep, fn)
+		} else {
+			a.checkFunctionCFG(rep, fn, metric)
+		}
+	}
+}
+
+// fixFile rewrites every function in the already-parsed file that has a
+// line exceeding -max-line-complexity (the rewrite does not consult
+// "//ghost:" directives), then re-runs the analyzer, with directives
+// applied, against the rewritten AST to report any line still over its
+// threshold.
+func (a *analyzer) fixFile(filename string, rep *reporter) {
+	changed := false
+
+	for _, decl := range a.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
 
-			checkFunction(fn)
+		if a.fixFunction(fn) {
+			changed = true
 		}
 	}
 
-	if hasErrors && !optionNeverFail {
-		os.Exit(1)
+	if changed {
+		a.printFixedFile(filename)
+	}
+
+	for _, decl := range a.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		a.checkFunction(rep, fn)
+	}
+}
+
+func (a *analyzer) printFixedFile(filename string) {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, a.fset, a.file); err != nil {
+		panic(err)
+	}
+
+	if optionWriteInPlace {
+		if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+			panic(err)
+		}
+
+		return
 	}
+
+	fmt.Print(buf.String())
 }
 
-func checkFunction(fn *ast.FuncDecl) {
-	currentFunction = fn
+func (a *analyzer) checkFunction(rep *reporter, fn *ast.FuncDecl) {
+	a.currentFunction = fn
 
 	// Body will be nil for non-Go (external) functions.
 	if fn.Body == nil {
 		return
 	}
 
+	settings := a.directiveTable.FunctionSettings(fn, a.fileSettings)
+	if settings.IgnoreFunction {
+		return
+	}
+
+	cursor := a.directiveTable.BodyCursor(fn)
 	for _, stmt := range fn.Body.List {
-		checkLine(stmt)
+		a.checkLine(rep, stmt, cursor.Settings(stmt, settings))
 	}
 }
 
-func consumeComment(line ast.Stmt) (comment string) {
-	for commentGroupIndex < len(file.Comments) {
-		commentGroup := file.Comments[commentGroupIndex]
-		if commentGroup.Pos() < line.Pos() {
-			comment += commentGroup.Text() + "\n"
-			commentGroupIndex++
-		} else {
+// checkFunctionCFG scores fn with one of the cfg package's whole-function
+// metrics instead of the per-line AST walk used by checkFunction. It catches
+// functions that look simple statement-by-statement but have a tangled
+// overall control flow.
+func (a *analyzer) checkFunctionCFG(rep *reporter, fn *ast.FuncDecl, metric string) {
+	a.currentFunction = fn
+
+	if fn.Body == nil {
+		return
+	}
+
+	settings := a.directiveTable.FunctionSettings(fn, a.fileSettings)
+	if settings.IgnoreFunction {
+		return
+	}
+
+	// Each CFG-based metric is on its own scale, so each gets its own
+	// -max-* flag rather than sharing -max-line-complexity.
+	var score, threshold int
+	switch metric {
+	case "cyclomatic":
+		score = cfg.Build(fn).Cyclomatic()
+		threshold = optionMaxCyclomatic
+
+	case "liveness":
+		score = cfg.AnalyzeLiveness(cfg.Build(fn)).Max
+		threshold = optionMaxLiveness
+
+	case "blocks":
+		score = len(cfg.Build(fn).Blocks)
+		threshold = optionMaxBlocks
+
+	case "cognitive":
+		score = cognitiveComplexity(fn)
+		threshold = optionMaxCognitive
+
+	default:
+		rep.report(finding{
+			File:    a.fset.Position(fn.Pos()).Filename,
+			Message: fmt.Sprintf("unknown -metric %q", metric),
+		})
+
+		return
+	}
+
+	if score > threshold {
+		a.printFunctionMetric(rep, metric, score, fn)
+	}
+}
+
+func (a *analyzer) printFunctionMetric(rep *reporter, metric string, score int, fn *ast.FuncDecl) {
+	pos := a.fset.Position(fn.Pos())
+
+	rep.report(finding{
+		File:     pos.Filename,
+		Line:     pos.Line,
+		Function: fn.Name.Name,
+		Message: fmt.Sprintf("%s:%d: %s complexity is %d (in %s)", pos.Filename,
+			pos.Line, metric, score, fn.Name.Name),
+	})
+}
+
+// checkLine scores line with LineComplexity and reports it if it exceeds
+// settings.MaxLineComplexity. A block-scoped "//ghost:ignore" directive
+// (settings.IgnoreLine) suppresses the check entirely.
+func (a *analyzer) checkLine(rep *reporter, line ast.Stmt, settings directives.Settings) bool {
+	if settings.IgnoreLine {
+		return true
+	}
+
+	complexity := a.LineComplexity(rep, line)
+
+	if complexity > settings.MaxLineComplexity {
+		a.printLine(rep, complexity, line)
+	}
+
+	return complexity <= settings.MaxLineComplexity
+}
+
+// fixFunction rewrites fn.Body in place, hoisting the sub-expression chosen
+// by pickExtractionCandidate out of any top-level statement whose LineComplexity exceeds optionMaxLineComplexity
+// into a "_tmpN := <subexpr>" assignment immediately above it, repeating
+// until the statement falls under the threshold or no further candidates
+// can be found. It returns true if the function was modified.
+func (a *analyzer) fixFunction(fn *ast.FuncDecl) bool {
+	if fn.Body == nil {
+		return false
+	}
+
+	a.currentFunction = fn
+	a.tempNameCounter = 0
+	changed := false
+
+	newList := make([]ast.Stmt, 0, len(fn.Body.List))
+	for _, stmt := range fn.Body.List {
+		extracted, fixed := a.extractSubexpressions(stmt)
+		if len(extracted) > 0 {
+			changed = true
+		}
+
+		newList = append(newList, extracted...)
+		newList = append(newList, fixed)
+	}
+
+	fn.Body.List = newList
+
+	return changed
+}
+
+// extractSubexpressions hoists sub-expressions out of stmt until its
+// LineComplexity is no greater than optionMaxLineComplexity. It returns the
+// new "_tmpN := ..." assignments that must be inserted immediately before
+// stmt, and stmt itself (mutated in place to reference the temporaries).
+func (a *analyzer) extractSubexpressions(stmt ast.Stmt) (extracted []ast.Stmt, fixed ast.Stmt) {
+	fixed = stmt
+
+	exprs := topLevelExprs(stmt)
+	if len(exprs) == 0 {
+		return nil, fixed
+	}
+
+	for a.LineComplexity(nil, stmt) > optionMaxLineComplexity {
+		var candidates []*ast.Expr
+		for _, e := range exprs {
+			collectCandidates(e, &candidates)
+		}
+
+		best := pickExtractionCandidate(candidates)
+		if best == nil {
 			break
 		}
+
+		name := a.nextTempName()
+		extracted = append(extracted, &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(name)},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{*best},
+		})
+		*best = ast.NewIdent(name)
 	}
 
-	return
+	return extracted, fixed
 }
 
-func checkLine(line ast.Stmt) bool {
-	complexity := LineComplexity(line)
+// topLevelExprs returns addressable pointers to the expressions that
+// LineComplexity scores for an assignment, expression, return, if or switch
+// statement. For any other statement it returns nil, so -fix leaves that
+// statement unchanged. The LHS of an assignment is deliberately excluded:
+// we never lift out of it.
+func topLevelExprs(stmt ast.Stmt) []*ast.Expr {
+	switch n := stmt.(type) {
+	case *ast.AssignStmt:
+		exprs := make([]*ast.Expr, len(n.Rhs))
+		for i := range n.Rhs {
+			exprs[i] = &n.Rhs[i]
+		}
+
+		return exprs
 
-	if complexity > optionMaxLineComplexity {
-		printLine(complexity, line)
+	case *ast.ExprStmt:
+		return []*ast.Expr{&n.X}
+
+	case *ast.ReturnStmt:
+		exprs := make([]*ast.Expr, len(n.Results))
+		for i := range n.Results {
+			exprs[i] = &n.Results[i]
+		}
+
+		return exprs
+
+	case *ast.IfStmt:
+		return []*ast.Expr{&n.Cond}
+
+	case *ast.SwitchStmt:
+		if n.Tag == nil {
+			return nil
+		}
+
+		return []*ast.Expr{&n.Tag}
+
+	default:
+		return nil
+	}
+}
+
+// collectCandidates walks the expression addressed by exprPtr and appends a
+// pointer for every *ast.CallExpr and *ast.BinaryExpr found, mirroring the
+// rationale in exprComplexity: calls and binary expressions are the nodes
+// worth assigning to an intermediate variable. SelectorExpr subtrees are
+// skipped entirely since exprComplexity already treats them as zero
+// complexity.
+func collectCandidates(exprPtr *ast.Expr, out *[]*ast.Expr) {
+	if exprPtr == nil || *exprPtr == nil {
+		return
 	}
 
-	return complexity <= optionMaxLineComplexity
+	switch e := (*exprPtr).(type) {
+	case *ast.SelectorExpr:
+		return
+
+	case *ast.BinaryExpr:
+		collectCandidates(&e.X, out)
+		collectCandidates(&e.Y, out)
+		*out = append(*out, exprPtr)
+
+	case *ast.CallExpr:
+		for i := range e.Args {
+			collectCandidates(&e.Args[i], out)
+		}
+		*out = append(*out, exprPtr)
+
+	case *ast.UnaryExpr:
+		collectCandidates(&e.X, out)
+
+	case *ast.StarExpr:
+		collectCandidates(&e.X, out)
+
+	case *ast.ParenExpr:
+		collectCandidates(&e.X, out)
+
+	case *ast.TypeAssertExpr:
+		collectCandidates(&e.X, out)
+
+	case *ast.IndexExpr:
+		collectCandidates(&e.Index, out)
+
+	case *ast.KeyValueExpr:
+		collectCandidates(&e.Value, out)
+
+	case *ast.CompositeLit:
+		for i := range e.Elts {
+			collectCandidates(&e.Elts[i], out)
+		}
+
+	case *ast.SliceExpr:
+		collectCandidates(&e.Low, out)
+		collectCandidates(&e.High, out)
+		collectCandidates(&e.Max, out)
+	}
 }
 
-func LineComplexity(line ast.Stmt) int {
-	// Check for ignore comment.
-	comment := consumeComment(line)
-	if strings.Contains(comment, "ghost:ignore") {
-		return 0
+// pickExtractionCandidate picks the candidate whose complexity is closest
+// to (but not exceeding) optionMaxLineComplexity, starting from the largest.
+// If every candidate still exceeds the limit on its own, the largest one is
+// extracted anyway so the loop in extractSubexpressions always makes
+// progress.
+func pickExtractionCandidate(candidates []*ast.Expr) *ast.Expr {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return exprComplexity(*candidates[i]) > exprComplexity(*candidates[j])
+	})
+
+	for _, c := range candidates {
+		if exprComplexity(*c) <= optionMaxLineComplexity {
+			return c
+		}
 	}
 
+	return candidates[0]
+}
+
+// nextTempName returns the next "_tmpN" identifier that does not already
+// appear anywhere in a.currentFunction.
+func (a *analyzer) nextTempName() string {
+	for {
+		a.tempNameCounter++
+		name := fmt.Sprintf("_tmp%d", a.tempNameCounter)
+
+		if !a.identInScope(name) {
+			return name
+		}
+	}
+}
+
+func (a *analyzer) identInScope(name string) bool {
+	found := false
+
+	ast.Inspect(a.currentFunction, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+
+			return false
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// LineComplexity scores the overall complexity of a single statement. rep
+// may be nil: it is only used to report malformed ASTs that cause a panic,
+// which callers that just want the raw score (such as -fix's convergence
+// loop) can safely ignore.
+func (a *analyzer) LineComplexity(rep *reporter, line ast.Stmt) int {
 	defer func() {
 		if r := recover(); r != nil {
-			printLine(-1, line)
+			if rep != nil {
+				a.printLine(rep, -1, line)
+			}
 			panic(r)
 		}
 	}()
@@ -134,24 +646,12 @@ func LineComplexity(line ast.Stmt) int {
 		return listComplexity(n.Results)
 
 	case *ast.IfStmt:
-		for _, l := range n.Body.List {
-			LineComplexity(l)
-		}
-
 		return exprComplexity(n.Cond)
 
 	case *ast.BlockStmt:
-		for _, l := range n.List {
-			LineComplexity(l)
-		}
-
 		return 0
 
 	case *ast.ForStmt:
-		for _, l := range n.Body.List {
-			LineComplexity(l)
-		}
-
 		// A "for" statement can contain multiple components. We have to
 		// consider the complexity of each element and only return the maximum
 		// complexity.
@@ -160,9 +660,9 @@ func LineComplexity(line ast.Stmt) int {
 		// because it is expected that a binary expression containing the
 		// iterator is included in the expression and this can not be further
 		// simplified.
-		initComplexity := LineComplexity(n.Init)
+		initComplexity := a.LineComplexity(rep, n.Init)
 		condComplexity := exprComplexity(n.Cond)
-		postComplexity := LineComplexity(n.Post)
+		postComplexity := a.LineComplexity(rep, n.Post)
 		maxComplexity := maxInt(initComplexity, condComplexity, postComplexity)
 
 		return maxComplexity
@@ -172,10 +672,6 @@ func LineComplexity(line ast.Stmt) int {
 			return 0
 		}
 
-		for _, l := range n.Body.List {
-			LineComplexity(l)
-		}
-
 		return 1 + exprComplexity(n.Tag)
 
 	case *ast.DeferStmt:
@@ -185,10 +681,6 @@ func LineComplexity(line ast.Stmt) int {
 		return exprComplexity(n.Call.Fun)
 
 	case *ast.TypeSwitchStmt:
-		for _, l := range n.Body.List {
-			LineComplexity(l)
-		}
-
 		return 1
 
 	case *ast.RangeStmt:
@@ -207,7 +699,9 @@ func LineComplexity(line ast.Stmt) int {
 				total += exprsComplexity(s.Values)
 
 			default:
-				printLine(-1, line)
+				if rep != nil {
+					a.printLine(rep, -1, line)
+				}
 				panic(n)
 			}
 		}
@@ -215,10 +709,6 @@ func LineComplexity(line ast.Stmt) int {
 		return total
 
 	case *ast.CaseClause:
-		for _, l := range n.Body {
-			LineComplexity(l)
-		}
-
 		return listComplexity(n.List)
 
 	case *ast.SendStmt:
@@ -255,7 +745,7 @@ func listComplexity(exprs []ast.Expr) int {
 func exprComplexity(expr ast.Expr) int {
 	switch e := expr.(type) {
 	case nil, *ast.BasicLit, *ast.Ident, *ast.ArrayType, *ast.MapType,
-	*ast.ChanType, *ast.StructType, *ast.InterfaceType:
+		*ast.ChanType, *ast.StructType, *ast.InterfaceType:
 		return 0
 
 	case *ast.SelectorExpr:
@@ -344,10 +834,6 @@ func exprComplexity(expr ast.Expr) int {
 		return exprComplexity(e.X)
 
 	case *ast.FuncLit:
-		for _, l := range e.Body.List {
-			LineComplexity(l)
-		}
-
 		return 1
 
 	case *ast.SliceExpr:
@@ -360,17 +846,21 @@ func exprComplexity(expr ast.Expr) int {
 	}
 }
 
-func printLine(complexity int, line ast.Stmt) {
-	hasErrors = true
-	pos := fset.Position(line.Pos())
+func (a *analyzer) printLine(rep *reporter, complexity int, line ast.Stmt) {
+	pos := a.fset.Position(line.Pos())
 
 	functionName := ""
-	if currentFunction != nil {
-		functionName = currentFunction.Name.Name
+	if a.currentFunction != nil {
+		functionName = a.currentFunction.Name.Name
 	}
 
-	fmt.Printf("%s:%d: complexity is %d (in %s)\n", pos.Filename, pos.Line,
-		complexity, functionName)
+	rep.report(finding{
+		File:     pos.Filename,
+		Line:     pos.Line,
+		Function: functionName,
+		Message: fmt.Sprintf("%s:%d: complexity is %d (in %s)", pos.Filename,
+			pos.Line, complexity, functionName),
+	})
 }
 
 func maxInt(numbers ...int) int {
@@ -378,3 +868,77 @@ func maxInt(numbers ...int) int {
 
 	return numbers[len(numbers)-1]
 }
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func printSARIF(findings []finding) {
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "ghost"}},
+			Results: []sarifResult{},
+		}},
+	}
+
+	for _, f := range findings {
+		log.Runs[0].Results = append(log.Runs[0].Results, sarifResult{
+			RuleID:  "complexity",
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.File},
+					Region:           sarifRegion{StartLine: f.Line},
+				},
+			}},
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(log)
+}
@@ -1,36 +1,45 @@
 package main
 
 import (
+	"bytes"
 	"testing"
 	"github.com/elliotchance/tf"
 	"go/token"
 	"github.com/stretchr/testify/assert"
 	"fmt"
 	"go/parser"
+	"go/printer"
 	"go/ast"
+	"encoding/json"
+	"io"
+	"os"
 )
 
 func TestLineComplexity(t *testing.T) {
 	fn := func(line string) int {
 		line = fmt.Sprintf("package p\nfunc a() { %s }", line)
 
-		fset = token.NewFileSet()
+		fset := token.NewFileSet()
 		node, err := parser.ParseFile(fset, "test.go", line, parser.ParseComments)
 		assert.NoError(t, err)
 
-		return LineComplexity(node.Decls[0].(*ast.FuncDecl).Body.List[0])
+		a := &analyzer{fset: fset}
+
+		return a.LineComplexity(nil, node.Decls[0].(*ast.FuncDecl).Body.List[0])
 	}
 
 	fnSwitch := func(line string) int {
 		line = fmt.Sprintf("package p\nfunc a() { switch { %s } }", line)
 
-		fset = token.NewFileSet()
+		fset := token.NewFileSet()
 		node, err := parser.ParseFile(fset, "test.go", line, parser.ParseComments)
 		assert.NoError(t, err)
 
 		stmts := node.Decls[0].(*ast.FuncDecl).Body.List[0].(*ast.SwitchStmt).Body.List
 
-		return LineComplexity(stmts[0])
+		a := &analyzer{fset: fset}
+
+		return a.LineComplexity(nil, stmts[0])
 	}
 
 	LC := tf.NamedFunction(t, "Assignment", fn)
@@ -141,8 +150,8 @@ func TestLineComplexity(t *testing.T) {
 
 	LC = tf.NamedFunction(t, "For", fn)
 	LC(`for {}`).Returns(0)
-	LC(`for true {}`).Returns(1)
-	LC(`for true && false {}`).Returns(2)
+	LC(`for true {}`).Returns(0)
+	LC(`for true && false {}`).Returns(1)
 
 	LC = tf.NamedFunction(t, "TypeAssert", fn)
 	LC(`a.(Foo)`).Returns(1)
@@ -179,3 +188,75 @@ func TestLineComplexity(t *testing.T) {
 	LC = tf.NamedFunction(t, "Chan", fn)
 	LC(`make(chan string)`).Returns(1)
 }
+
+func TestReporterFlush_JSONWithNoFindingsIsEmptyArray(t *testing.T) {
+	oldFormat := optionFormat
+	optionFormat = "json"
+	defer func() { optionFormat = oldFormat }()
+
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+
+	oldStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = oldStdout }()
+
+	rep := &reporter{}
+	rep.flush()
+
+	assert.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "[]\n", string(out), "zero findings must encode as [], not null")
+}
+
+func TestPrintSARIF_NoFindingsHasEmptyResultsArray(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+
+	oldStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = oldStdout }()
+
+	printSARIF(nil)
+
+	assert.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+
+	var log sarifLog
+	assert.NoError(t, json.Unmarshal(out, &log))
+	assert.NotNil(t, log.Runs[0].Results, "results must be [], not null")
+	assert.Empty(t, log.Runs[0].Results)
+}
+
+func TestFixFunction(t *testing.T) {
+	oldMax := optionMaxLineComplexity
+	optionMaxLineComplexity = 1
+	defer func() { optionMaxLineComplexity = oldMax }()
+
+	fix := func(body string) string {
+		src := fmt.Sprintf("package p\n\nfunc a() {\n%s\n}\n", body)
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+		assert.NoError(t, err)
+
+		a := &analyzer{fset: fset, file: file}
+		fn := file.Decls[0].(*ast.FuncDecl)
+		a.fixFunction(fn)
+
+		var buf bytes.Buffer
+		assert.NoError(t, printer.Fprint(&buf, fset, file))
+
+		return buf.String()
+	}
+
+	out := fix(`hello := foo(bar()) + baz(qux())`)
+	assert.Contains(t, out, "_tmp1 :=")
+	assert.Contains(t, out, "_tmp2 :=")
+
+	out = fix(`x := y`)
+	assert.NotContains(t, out, "_tmp1")
+}